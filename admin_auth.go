@@ -0,0 +1,30 @@
+package main
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenMiddleware gates operator-facing endpoints (forcing a full
+// corpus rescan, dumping ranked search results) behind a shared secret, since
+// unlike /ascii and /interact these aren't meant to be reachable by anyone
+// who can reach the bot at all. It checks the X-Admin-Token header against
+// token using a constant-time comparison. If token is empty, the check is
+// skipped so local development doesn't require one.
+func AdminTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		given := c.GetHeader("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatus(401)
+			return
+		}
+
+		c.Next()
+	}
+}
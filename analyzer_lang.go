@@ -0,0 +1,183 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kljensen/snowball"
+	analyzer "github.com/rekki/go-query-analyze"
+	norm "github.com/rekki/go-query-analyze/normalize"
+	"github.com/rekki/go-query-analyze/tokenize"
+)
+
+// Lang identifies one of the morphology/stopword chains GetAnalyzerChain
+// knows how to build. Adding a new language means teaching snowballLang
+// about it and dropping a stopwords file next to the others.
+type Lang string
+
+const (
+	LangEnglish Lang = "en"
+	LangRussian Lang = "ru"
+)
+
+// Languages is every language ascii-bot builds an analyzer chain for. The
+// index ends up with one analyzer per (field, language) pair, see
+// FieldForLang.
+var Languages = []Lang{LangEnglish, LangRussian}
+
+// DefaultLang is used for art that carries no `lang:` frontmatter tag.
+var DefaultLang = LangEnglish
+
+// snowballLang maps a Lang to the language name github.com/kljensen/snowball
+// expects.
+var snowballLang = map[Lang]string{
+	LangEnglish: "english",
+	LangRussian: "russian",
+}
+
+// IsSupportedLang reports whether lang has a registered analyzer chain.
+// Indexing art under an unsupported lang would silently fall back to the
+// library's generic DefaultAnalyzer and never be queried by
+// (*Searcher).query, which only loops over Languages, so callers must check
+// this and fail loudly instead of indexing it anyway.
+func IsSupportedLang(lang Lang) bool {
+	_, ok := snowballLang[lang]
+	return ok
+}
+
+// FieldForLang returns the per-language index field name for a base field,
+// e.g. FieldForLang("blob", LangRussian) == "blob_ru". A single MemOnlyIndex
+// only has one analyzer per field name, so language-specific chains are
+// selected by giving each language its own field.
+func FieldForLang(field string, lang Lang) string {
+	return field + "_" + string(lang)
+}
+
+func stem(word string, lang Lang) string {
+	name, ok := snowballLang[lang]
+	if !ok {
+		name = snowballLang[DefaultLang]
+	}
+	stemmed, err := snowball.Stem(word, name, false)
+	if err != nil {
+		return word
+	}
+	return stemmed
+}
+
+// stemmerTokenizer stems every token with the snowball stemmer for lang.
+func stemmerTokenizer(lang Lang) tokenize.Tokenizer {
+	return tokenize.NewCustom(func(in []tokenize.Token) []tokenize.Token {
+		out := make([]tokenize.Token, 0, len(in))
+		for _, t := range in {
+			out = append(out, t.Clone(stem(t.Text, lang)))
+		}
+		return out
+	})
+}
+
+// stopwordTokenizer drops any token found in stopwords.
+func stopwordTokenizer(stopwords map[string]bool) tokenize.Tokenizer {
+	return tokenize.NewCustom(func(in []tokenize.Token) []tokenize.Token {
+		if len(stopwords) == 0 {
+			return in
+		}
+		out := make([]tokenize.Token, 0, len(in))
+		for _, t := range in {
+			if !stopwords[t.Text] {
+				out = append(out, t)
+			}
+		}
+		return out
+	})
+}
+
+// GetAnalyzerChain builds the shingling analyzer chain for lang, additionally
+// dropping stopwords and stemming tokens so art described in languages other
+// than English becomes searchable through morphological normalization
+// rather than exact token match.
+func GetAnalyzerChain(lang Lang, stopwords map[string]bool) *analyzer.Analyzer {
+	normalizers := []norm.Normalizer{
+		norm.NewUnaccent(),
+		norm.NewLowerCase(),
+		norm.NewSpaceBetweenDigits(),
+		norm.NewCustom(func(s string) string {
+			return strings.Replace(s, "#", " ", -1)
+		}),
+		norm.NewRemoveNonAlphanumeric(),
+		norm.NewTrim(" "),
+	}
+
+	searchTokenizer := []tokenize.Tokenizer{
+		tokenize.NewWhitespace(),
+		stopwordTokenizer(stopwords),
+		stemmerTokenizer(lang),
+	}
+
+	indexTokenizer := []tokenize.Tokenizer{
+		tokenize.NewWhitespace(),
+		stopwordTokenizer(stopwords),
+		stemmerTokenizer(lang),
+		tokenize.NewShingles(2),
+	}
+
+	return analyzer.NewAnalyzer(
+		normalizers,
+		searchTokenizer,
+		indexTokenizer,
+	)
+}
+
+// PerFieldAnalyzers builds the perField analyzer map index.NewMemOnlyIndex
+// expects, with one GetAnalyzerChain per (field, language) pair. stopwordDir
+// is searched for a "<lang>.txt" file per language, see LoadStopwords.
+func PerFieldAnalyzers(fields []string, stopwordDir string) map[string]*analyzer.Analyzer {
+	out := map[string]*analyzer.Analyzer{}
+	for _, lang := range Languages {
+		stopwords, err := LoadStopwords(stopwordsPath(stopwordDir, lang))
+		if err != nil {
+			panic(err)
+		}
+		chain := GetAnalyzerChain(lang, stopwords)
+		for _, field := range fields {
+			out[FieldForLang(field, lang)] = chain
+		}
+	}
+	return out
+}
+
+func stopwordsPath(dir string, lang Lang) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, string(lang)+".txt")
+}
+
+// LoadStopwords reads a newline-separated stopword list from path, one word
+// per line, `#`-prefixed lines treated as comments. A missing path is not an
+// error: it just means no stopwords are filtered for that language.
+func LoadStopwords(path string) (map[string]bool, error) {
+	out := map[string]bool{}
+	if path == "" {
+		return out, nil
+	}
+
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return out, nil
+		}
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(f), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out[line] = true
+	}
+	return out, nil
+}
@@ -0,0 +1,20 @@
+package main
+
+// Art is a single piece of ascii art loaded from the `-root` folder.
+type Art struct {
+	id   int
+	path string
+	blob string
+	tags []string
+	lang Lang
+}
+
+func (a *Art) IndexableFields() map[string][]string {
+	out := map[string][]string{}
+
+	out["_id"] = []string{a.path}
+	out[FieldForLang("blob", a.lang)] = []string{a.blob}
+	out[FieldForLang("tags", a.lang)] = a.tags
+	out["match_all"] = []string{"true"}
+	return out
+}
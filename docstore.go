@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var docsBucket = []byte("docs")
+
+// storedDoc is what DocStore keeps per art file, keyed by its path, so a
+// restart can tell whether a file actually changed since the last run
+// instead of having to re-read and re-parse it.
+type storedDoc struct {
+	ID   int32    `json:"id"`
+	Hash string   `json:"hash"`
+	Blob string   `json:"blob"`
+	Tags []string `json:"tags"`
+	Lang Lang     `json:"lang"`
+}
+
+// DocStore is a bbolt-backed persistence layer for the art corpus metadata:
+// (path, hash, id, tags). It lets the index subsystem skip re-reading and
+// re-tokenizing files whose content hasn't changed between restarts.
+type DocStore struct {
+	db *bolt.DB
+}
+
+// OpenDocStore opens (creating if necessary) the bbolt database at path.
+func OpenDocStore(path string) (*DocStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(docsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DocStore{db: db}, nil
+}
+
+func (s *DocStore) Close() error {
+	return s.db.Close()
+}
+
+// HashContent returns the content hash DocStore uses to detect file changes.
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the doc stored for path, and whether it was found.
+func (s *DocStore) Get(path string) (storedDoc, bool) {
+	var doc storedDoc
+	found := false
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(docsBucket).Get([]byte(path))
+		if v == nil {
+			return nil
+		}
+		found = json.Unmarshal(v, &doc) == nil
+		return nil
+	})
+	return doc, found
+}
+
+// Upsert persists hash/blob/tags/lang for path, reusing its existing ID if
+// path was already known, or assigning the next sequential one otherwise.
+func (s *DocStore) Upsert(path, hash, blob string, tags []string, lang Lang) (storedDoc, error) {
+	doc := storedDoc{Hash: hash, Blob: blob, Tags: tags, Lang: lang}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(docsBucket)
+
+		if v := b.Get([]byte(path)); v != nil {
+			var existing storedDoc
+			if err := json.Unmarshal(v, &existing); err == nil {
+				doc.ID = existing.ID
+			}
+		}
+		if doc.ID == 0 {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			doc.ID = int32(seq)
+		}
+
+		v, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(path), v)
+	})
+
+	return doc, err
+}
+
+// Delete removes the doc stored for path.
+func (s *DocStore) Delete(path string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(docsBucket).Delete([]byte(path))
+	})
+}
+
+// Paths returns every path currently tracked, used to prune docs for files
+// that were removed from disk between scans.
+func (s *DocStore) Paths() ([]string, error) {
+	var out []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(docsBucket).ForEach(func(k, _ []byte) error {
+			out = append(out, string(k))
+			return nil
+		})
+	})
+	return out, err
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+)
+
+// frontmatter is the small `---`-delimited header art files may carry ahead
+// of the actual blob, e.g.:
+//
+//	---
+//	lang: ru
+//	tags: кот собака
+//	---
+//	  /\_/\
+//	 ( o.o )
+type frontmatter struct {
+	lang Lang
+	tags []string
+}
+
+// parseFrontmatter splits a leading `---` frontmatter block off of raw (if
+// present) and returns it alongside the remaining body. Files without a
+// frontmatter block are returned unchanged with a zero-value frontmatter.
+func parseFrontmatter(raw string) (frontmatter, string) {
+	fm := frontmatter{}
+
+	if !strings.HasPrefix(raw, "---\n") {
+		return fm, raw
+	}
+
+	end := strings.Index(raw[4:], "\n---\n")
+	if end == -1 {
+		return fm, raw
+	}
+	end += 4
+
+	header := raw[4:end]
+	body := raw[end+len("\n---\n"):]
+
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "lang":
+			fm.lang = Lang(value)
+		case "tags":
+			fm.tags = strings.Fields(value)
+		}
+	}
+
+	return fm, body
+}
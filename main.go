@@ -1,235 +1,145 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
-	"fmt"
-	"io/ioutil"
 	"log"
-	"math/rand"
 	"os"
-	"path/filepath"
-	"strings"
-
-	iq "github.com/rekki/go-query"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	analyzer "github.com/rekki/go-query-analyze"
-	norm "github.com/rekki/go-query-analyze/normalize"
-	"github.com/rekki/go-query-analyze/tokenize"
 	index "github.com/rekki/go-query-index"
 )
 
-type Art struct {
-	id   int
-	blob string
-	tags []string
-}
-
-func (a *Art) Blocks(_qs string) []*Block {
-	return []*Block{
-		{
-			Type: "section",
-			Text: &Text{
-				Type: "mrkdwn", Text: fmt.Sprintf("```\n%s\n```", strings.Trim(a.blob, "\n")),
-			},
-		},
-	}
-}
+func main() {
+	root := flag.String("root", "./art", "folder")
+	lang := flag.String("lang", string(LangEnglish), "default language for art with no lang frontmatter tag")
+	stopwords := flag.String("stopwords", "", "folder containing one <lang>.txt stopword list per language")
+	indexDB := flag.String("index-db", "./ascii-bot.db", "bbolt database file persisting the art doc store")
+	topK := flag.Int("topk", 10, "how many of the top ranked results Shuffle can cycle through")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
 
-func (a *Art) Buttons(qs string) *Block {
-	return &Block{
-		BlockID: "action_123",
-		Type:    "actions",
-		Elements: []*Element{
-			{
-				Type: "button",
-				Text: &Text{
-					Type: "plain_text",
-					Text: "Post it!",
-				},
-				Style:    "primary",
-				Value:    fmt.Sprintf("%d/%s", a.id, qs),
-				ActionID: "post_it",
-			},
-			{
-				Type: "button",
-				Text: &Text{
-					Type: "plain_text",
-					Text: "Shuffle!",
-				},
-				ActionID: "shuffle",
-				Value:    qs,
-			},
-		},
+	signingSecret := os.Getenv("SLACK_SIGNING_SECRET")
+	if signingSecret == "" {
+		log.Printf("SLACK_SIGNING_SECRET not set, Slack request signatures will not be verified")
 	}
-}
 
-func (a *Art) IndexableFields() map[string][]string {
-	out := map[string][]string{}
-
-	out["blob"] = []string{a.blob}
-	out["tags"] = a.tags
-	out["match_all"] = []string{"true"}
-	return out
-}
-
-func toDocuments(in []*Art) []index.Document {
-	out := make([]index.Document, len(in))
-	for i, d := range in {
-		out[i] = index.Document(d)
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Printf("ADMIN_TOKEN not set, /reindex and /search will not require authentication")
 	}
-	return out
-}
 
-func GetShinglesAnalyzer() *analyzer.Analyzer {
-	andAmp := []norm.Normalizer{
-		norm.NewUnaccent(),
-		norm.NewLowerCase(),
-		norm.NewSpaceBetweenDigits(),
-		norm.NewCustom(func(s string) string {
-			return strings.Replace(s, "#", " ", -1)
-		}),
-		norm.NewRemoveNonAlphanumeric(),
-		norm.NewTrim(" "),
+	DefaultLang = Lang(*lang)
+	if !IsSupportedLang(DefaultLang) {
+		log.Fatalf("unsupported -lang %q, must be one of %v", *lang, Languages)
 	}
 
-	indexTokenizer := []tokenize.Tokenizer{
-		tokenize.NewWhitespace(),
-		tokenize.NewShingles(2),
+	store, err := OpenDocStore(*indexDB)
+	if err != nil {
+		panic(err)
 	}
+	defer store.Close()
 
-	return analyzer.NewAnalyzer(
-		andAmp,
-		index.DefaultSearchTokenizer,
-		indexTokenizer,
-	)
-}
+	perField := PerFieldAnalyzers([]string{"blob", "tags"}, *stopwords)
+	m := index.NewMemOnlyIndex(perField)
+	searcher := NewSearcher(m, perField)
 
-func prepare(root string) []*Art {
-	out := []*Art{}
-	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".txt") {
-			f, err := ioutil.ReadFile(p)
-			if err != nil {
-				return err
-			}
-			if len(f) > 3500 {
-				// wont fit
-				log.Printf("skipping %v, too big: %v", p, len(f))
-				return nil
-			}
-			art := &Art{
-				blob: string(f),
-				tags: []string{info.Name()},
-				id:   len(out),
-			}
-			out = append(out, art)
-		}
-		return nil
-	})
-	if err != nil {
+	rx := NewReindexer(*root, store, m, DefaultLang)
+	if err := rx.Scan(); err != nil {
 		panic(err)
 	}
-	return out
-}
 
-type PostMessage struct {
-	User    string   `json:"user"`
-	Channel string   `json:"channel"`
-	Blocks  []*Block `json:"blocks,omitempty"`
-}
+	watcher, err := NewWatcher(*root, rx)
+	if err != nil {
+		log.Printf("fsnotify watcher disabled: %v", err)
+	} else {
+		defer watcher.Close()
+		go watcher.Run()
+	}
 
-type SlackResponse struct {
-	ResponseType    string   `json:"response_type,omitempty"`
-	ReplaceOriginal bool     `json:"replace_original,omitempty"`
-	DeleteOriginal  bool     `json:"delete_original,omitempty"`
-	Blocks          []*Block `json:"blocks,omitempty"`
-}
+	r := gin.Default()
 
-type Text struct {
-	Type string `json:"type,omitempty"`
-	Text string `json:"text,omitempty"`
-}
-type Block struct {
-	Type     string     `json:"type,omitempty"`
-	Text     *Text      `json:"text,omitempty"`
-	BlockID  string     `json:"block_id,omitempty"`
-	Elements []*Element `json:"elements,omitempty"`
-}
+	slackAuth := SlackSignatureMiddleware(signingSecret)
+	adminAuth := AdminTokenMiddleware(adminToken)
 
-type Element struct {
-	Type     string `json:"type,omitempty"`
-	Style    string `json:"style,omitempty"`
-	URL      string `json:"url,omitempty"`
-	Value    string `json:"value,omitempty"`
-	ActionID string `json:"action_id,omitempty"`
-	Text     *Text  `json:"text,omitempty"`
-}
+	asciiHandler := func(c *gin.Context) {
+		platform := c.Param("platform")
+		if platform == "" {
+			platform = c.GetHeader("X-Platform")
+		}
+		renderer := rendererFor(platform)
 
-func main() {
-	root := flag.String("root", "./art", "folder")
-	flag.Parse()
+		qs := c.PostForm("text")
+		art := searcher.Best(qs, *topK)
+		if art == nil {
+			renderer.RenderNotFound(c, qs)
+			return
+		}
 
-	ana := GetShinglesAnalyzer()
-	m := index.NewMemOnlyIndex(map[string]*analyzer.Analyzer{
-		"blob": ana,
-		"tags": ana,
-	})
+		renderer.RenderArt(c, art, qs, searcher.Match(art, qs), NewShuffleState())
+	}
 
-	list := prepare(*root)
-	m.Index(toDocuments(list)...)
+	r.POST("/ascii", slackAuth, asciiHandler)
+	// /ascii/:platform is shared with the other chat adapters (Discord,
+	// Mattermost, IRC). None of them has a request-signing scheme
+	// implemented yet, so rather than leave it open to anyone it's gated
+	// behind the same shared admin token as /reindex and /search until
+	// real per-platform verification lands.
+	r.POST("/ascii/:platform", adminAuth, asciiHandler)
+
+	r.POST("/interact", slackAuth, func(c *gin.Context) {
+		var payload struct {
+			Actions []struct {
+				ActionID string `json:"action_id"`
+				Value    string `json:"value"`
+			} `json:"actions"`
+		}
+		if err := json.Unmarshal([]byte(c.PostForm("payload")), &payload); err != nil || len(payload.Actions) == 0 {
+			c.Status(400)
+			return
+		}
 
-	r := gin.Default()
+		action := payload.Actions[0]
+		if action.ActionID != "shuffle" {
+			c.Status(200)
+			return
+		}
 
-	search := func(qs string) *Art {
-		q := iq.DisMax(0.1, iq.Or(m.Terms("tags", qs)...), iq.Or(m.Terms("blob", qs)...))
-
-		out := &Art{}
-		max := float32(0)
-		found := false
-		m.Foreach(q, func(did int32, score float32, doc index.Document) {
-			score = float32(rand.Int31())
-			art := doc.(*Art)
-			if score > max {
-				out = art
-				max = score
-			}
-			found = true
-		})
-
-		if found {
-			return out
+		state, qs, ok := ParseShuffleValue(action.Value)
+		if !ok {
+			c.Status(400)
+			return
 		}
-		return nil
-	}
 
-	r.POST("/ascii", func(c *gin.Context) {
-		qs := c.PostForm("text")
-		art := search(qs)
+		candidates := searcher.RankedCandidates(qs, *topK)
+		art := PickShuffled(candidates, state.Seed, state.Counter)
 		if art == nil {
-			response := SlackResponse{
-				Blocks: []*Block{
-					{
-						Type: "section",
-						Text: &Text{
-							Type: "mrkdwn", Text: fmt.Sprintf("```\n%s\n```", "couldnt find anything.... try something else or help me to add more ascii art"),
-						},
-					},
-				},
-			}
-			c.JSON(200, response)
+			renderers[DefaultRendererName].RenderNotFound(c, qs)
+			return
+		}
+
+		renderers[DefaultRendererName].RenderArt(c, art, qs, searcher.Match(art, qs), state.Next())
+	})
+
+	r.POST("/reindex", adminAuth, func(c *gin.Context) {
+		if err := rx.Scan(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+		c.JSON(200, gin.H{"status": "ok"})
+	})
 
-		response := SlackResponse{
-			ResponseType: "in_channel",
-			Blocks:       art.Blocks(qs),
+	r.GET("/search", adminAuth, func(c *gin.Context) {
+		qs := c.Query("q")
+		limit := 10
+		if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+			limit = n
 		}
-		c.JSON(200, &response)
+		c.JSON(200, gin.H{"hits": searcher.TopN(qs, limit)})
 	})
 
-	r.Run()
+	if err := RunGracefully(r, *addr); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	index "github.com/rekki/go-query-index"
+)
+
+// maxArtSize mirrors the original startup limit: files bigger than this
+// won't fit in a single chat message block, so they're skipped.
+const maxArtSize = 3500
+
+// Reindexer keeps a MemOnlyIndex and a DocStore in sync with the .txt files
+// under root. It hashes every file it sees and only re-parses and re-indexes
+// the ones whose content actually changed, so a restart (or a forced
+// /reindex) doesn't have to pay full tokenization cost for a corpus that
+// hasn't moved.
+type Reindexer struct {
+	root        string
+	store       *DocStore
+	index       *index.MemOnlyIndex
+	defaultLang Lang
+
+	mu sync.Mutex
+}
+
+func NewReindexer(root string, store *DocStore, idx *index.MemOnlyIndex, defaultLang Lang) *Reindexer {
+	return &Reindexer{root: root, store: store, index: idx, defaultLang: defaultLang}
+}
+
+// Scan walks root, indexing any new or changed .txt file and removing any
+// previously indexed file that's no longer present on disk.
+func (rx *Reindexer) Scan() error {
+	rx.mu.Lock()
+	defer rx.mu.Unlock()
+
+	seen := map[string]bool{}
+
+	err := filepath.Walk(rx.root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".txt") {
+			return nil
+		}
+		seen[p] = true
+		return rx.indexFileLocked(p, info)
+	})
+	if err != nil {
+		return err
+	}
+
+	known, err := rx.store.Paths()
+	if err != nil {
+		return err
+	}
+	for _, p := range known {
+		if !seen[p] {
+			rx.removeLocked(p)
+		}
+	}
+	return nil
+}
+
+// IndexPath (re-)indexes a single file, called by the fsnotify watcher when
+// a file under root is created or written to. It's a no-op if the file's
+// content hasn't actually changed.
+func (rx *Reindexer) IndexPath(p string) error {
+	rx.mu.Lock()
+	defer rx.mu.Unlock()
+
+	if !strings.HasSuffix(p, ".txt") {
+		return nil
+	}
+
+	info, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			rx.removeLocked(p)
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	return rx.indexFileLocked(p, info)
+}
+
+// RemovePath drops p from the index and the doc store, called by the
+// fsnotify watcher when a file under root is removed or renamed away.
+func (rx *Reindexer) RemovePath(p string) {
+	rx.mu.Lock()
+	defer rx.mu.Unlock()
+	rx.removeLocked(p)
+}
+
+func (rx *Reindexer) indexFileLocked(p string, info os.FileInfo) error {
+	if info.Size() > maxArtSize {
+		log.Printf("skipping %v, too big: %v", p, info.Size())
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	hash := HashContent(raw)
+
+	existing, existed := rx.store.Get(p)
+	if existed && existing.Hash == hash {
+		// Content hasn't changed, so skip the parse/hash-store round trip,
+		// but the in-memory index is rebuilt empty on every process start
+		// and still needs this doc back, so index it from the stored record.
+		// DeleteByID first in case it's already in this index (e.g. a
+		// no-op fsnotify write) so it isn't indexed twice under it.
+		rx.index.DeleteByID(p)
+		rx.index.Index(index.Document(&Art{
+			id:   int(existing.ID),
+			path: p,
+			blob: existing.Blob,
+			tags: existing.Tags,
+			lang: existing.Lang,
+		}))
+		return nil
+	}
+
+	fm, body := parseFrontmatter(string(raw))
+	lang := fm.lang
+	if lang == "" {
+		lang = rx.defaultLang
+	} else if !IsSupportedLang(lang) {
+		return fmt.Errorf("%s: unsupported lang frontmatter %q, must be one of %v", p, lang, Languages)
+	}
+	tags := append([]string{filepath.Base(p)}, fm.tags...)
+
+	if existed {
+		rx.index.DeleteByID(p)
+	}
+
+	doc, err := rx.store.Upsert(p, hash, body, tags, lang)
+	if err != nil {
+		return err
+	}
+
+	rx.index.Index(index.Document(&Art{
+		id:   int(doc.ID),
+		path: p,
+		blob: body,
+		tags: tags,
+		lang: lang,
+	}))
+
+	return nil
+}
+
+func (rx *Reindexer) removeLocked(p string) {
+	rx.index.DeleteByID(p)
+	_ = rx.store.Delete(p)
+}
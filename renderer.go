@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Renderer knows how to turn a matched Art (or a failed search) into a
+// platform-specific chat response and write it to the response writer.
+// Adding support for a new chat network means implementing this interface
+// and registering it with RegisterRenderer, without touching the search
+// or indexing code at all.
+type Renderer interface {
+	// Name is the adapter identifier used to select this renderer, e.g.
+	// "slack", "discord", "mattermost" or "irc".
+	Name() string
+
+	// RenderArt writes the response for a matched Art to qs, annotated with
+	// why it matched. shuffle is the state to embed in the "Shuffle!"
+	// button so the next click advances deterministically through the
+	// ranked candidate list instead of re-rolling from scratch. Only
+	// Slack's /interact endpoint actually services button clicks today;
+	// the Discord and Mattermost adapters render the same buttons for
+	// parity but are slash-command-only until each gets its own callback.
+	RenderArt(c *gin.Context, a *Art, qs string, match MatchSummary, shuffle ShuffleState)
+
+	// RenderNotFound writes the response for a search that produced no hits.
+	RenderNotFound(c *gin.Context, qs string)
+}
+
+var renderers = map[string]Renderer{}
+
+// RegisterRenderer makes a Renderer available for dispatch under its Name().
+func RegisterRenderer(r Renderer) {
+	renderers[r.Name()] = r
+}
+
+// DefaultRendererName is used when a request names no adapter, preserving
+// the original Slack-only behaviour of /ascii.
+const DefaultRendererName = "slack"
+
+// rendererFor resolves the adapter named by name, falling back to the
+// default (Slack) renderer if name is empty or unknown.
+func rendererFor(name string) Renderer {
+	if r, ok := renderers[name]; ok {
+		return r
+	}
+	return renderers[DefaultRendererName]
+}
+
+func init() {
+	RegisterRenderer(&SlackRenderer{})
+	RegisterRenderer(&DiscordRenderer{})
+	RegisterRenderer(&MattermostRenderer{})
+	RegisterRenderer(&IRCRenderer{})
+}
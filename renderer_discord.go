@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiscordEmbed is a (heavily trimmed down) Discord embed object, enough to
+// render a monospaced block of ascii art.
+// https://discord.com/developers/docs/resources/channel#embed-object
+type DiscordEmbed struct {
+	Description string         `json:"description"`
+	Footer      *DiscordFooter `json:"footer,omitempty"`
+}
+
+// DiscordFooter carries the match explanation in an embed's footer.
+type DiscordFooter struct {
+	Text string `json:"text"`
+}
+
+// DiscordComponent is a Discord message component, used here for the
+// "action row" containing the post/shuffle buttons. Clicking one fires a
+// MESSAGE_COMPONENT interaction at whatever Interactions Endpoint URL is
+// registered for the Discord application, which this repo doesn't implement
+// (there's no Ed25519 request verification or dispatch for it), so for now
+// these buttons render for parity with Slack but are no-ops when clicked.
+// https://discord.com/developers/docs/interactions/message-components
+type DiscordComponent struct {
+	Type       int                `json:"type"`
+	Style      int                `json:"style,omitempty"`
+	Label      string             `json:"label,omitempty"`
+	CustomID   string             `json:"custom_id,omitempty"`
+	Components []DiscordComponent `json:"components,omitempty"`
+}
+
+// DiscordResponse is the interaction response body Discord expects back
+// from a slash command webhook.
+// https://discord.com/developers/docs/interactions/receiving-and-responding
+type DiscordResponse struct {
+	Type int `json:"type"`
+	Data struct {
+		Embeds     []DiscordEmbed     `json:"embeds,omitempty"`
+		Content    string             `json:"content,omitempty"`
+		Components []DiscordComponent `json:"components,omitempty"`
+	} `json:"data"`
+}
+
+const (
+	discordComponentTypeActionRow = 1
+	discordComponentTypeButton    = 2
+	discordButtonStylePrimary     = 1
+	discordButtonStyleSecondary   = 2
+
+	// discordResponseTypeChannelMessageWithSource is InteractionResponseType 4.
+	// https://discord.com/developers/docs/interactions/receiving-and-responding#interaction-response-object-interaction-callback-type
+	discordResponseTypeChannelMessageWithSource = 4
+)
+
+// DiscordRenderer renders search results as a Discord interaction response
+// with an embed and a post/shuffle button row. It is currently
+// slash-command-only: see DiscordComponent on why the buttons themselves
+// don't yet do anything when clicked.
+type DiscordRenderer struct{}
+
+func (d *DiscordRenderer) Name() string {
+	return "discord"
+}
+
+func (d *DiscordRenderer) embeds(a *Art, match MatchSummary) []DiscordEmbed {
+	return []DiscordEmbed{
+		{
+			Description: fmt.Sprintf("```\n%s\n```", strings.Trim(a.blob, "\n")),
+			Footer:      &DiscordFooter{Text: match.String()},
+		},
+	}
+}
+
+func (d *DiscordRenderer) components(a *Art, qs string, shuffle ShuffleState) []DiscordComponent {
+	return []DiscordComponent{
+		{
+			Type: discordComponentTypeActionRow,
+			Components: []DiscordComponent{
+				{
+					Type:     discordComponentTypeButton,
+					Style:    discordButtonStylePrimary,
+					Label:    "Post it!",
+					CustomID: fmt.Sprintf("post_it:%d/%s", a.id, qs),
+				},
+				{
+					Type:     discordComponentTypeButton,
+					Style:    discordButtonStyleSecondary,
+					Label:    "Shuffle!",
+					CustomID: fmt.Sprintf("shuffle:%s", shuffle.Value(qs)),
+				},
+			},
+		},
+	}
+}
+
+func (d *DiscordRenderer) RenderArt(c *gin.Context, a *Art, qs string, match MatchSummary, shuffle ShuffleState) {
+	response := DiscordResponse{Type: discordResponseTypeChannelMessageWithSource}
+	response.Data.Embeds = d.embeds(a, match)
+	response.Data.Components = d.components(a, qs, shuffle)
+	c.JSON(200, &response)
+}
+
+func (d *DiscordRenderer) RenderNotFound(c *gin.Context, _qs string) {
+	response := DiscordResponse{Type: discordResponseTypeChannelMessageWithSource}
+	response.Data.Content = "couldnt find anything.... try something else or help me to add more ascii art"
+	c.JSON(200, &response)
+}
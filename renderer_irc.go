@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IRCRenderer renders search results as plain text, one PRIVMSG line per
+// line of art, since IRC has no notion of markdown or rich blocks.
+type IRCRenderer struct{}
+
+func (irc *IRCRenderer) Name() string {
+	return "irc"
+}
+
+func (irc *IRCRenderer) RenderArt(c *gin.Context, a *Art, _qs string, match MatchSummary, _shuffle ShuffleState) {
+	c.String(200, "%s\n-- %s", strings.Trim(a.blob, "\n"), match.String())
+}
+
+func (irc *IRCRenderer) RenderNotFound(c *gin.Context, _qs string) {
+	c.String(200, "couldnt find anything.... try something else or help me to add more ascii art")
+}
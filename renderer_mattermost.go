@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MattermostAttachment is a (trimmed down) Mattermost message attachment.
+// https://developers.mattermost.com/integrate/reference/message-attachments/
+type MattermostAttachment struct {
+	Text    string                   `json:"text"`
+	Footer  string                   `json:"footer,omitempty"`
+	Actions []MattermostAttachAction `json:"actions,omitempty"`
+}
+
+// MattermostAttachAction is an interactive button on an attachment. Integration.URL
+// is left empty: there is no registered callback endpoint for Mattermost yet
+// (unlike Slack's /interact), so these buttons render for parity with the
+// other adapters but are currently no-ops when clicked. Point Integration.URL
+// at a real callback route once one exists.
+type MattermostAttachAction struct {
+	Name        string `json:"name"`
+	Integration struct {
+		URL     string            `json:"url"`
+		Context map[string]string `json:"context,omitempty"`
+	} `json:"integration"`
+}
+
+// MattermostResponse is the JSON body expected by a Mattermost slash command.
+// https://developers.mattermost.com/integrate/slash-commands/custom/
+type MattermostResponse struct {
+	ResponseType string                 `json:"response_type"`
+	Text         string                 `json:"text,omitempty"`
+	Attachments  []MattermostAttachment `json:"attachments,omitempty"`
+}
+
+// MattermostRenderer renders search results as a Mattermost slash command
+// response with a code-formatted attachment and post/shuffle actions. It is
+// currently slash-command-only: see MattermostAttachAction on why the
+// actions themselves don't yet do anything when clicked.
+type MattermostRenderer struct{}
+
+func (mm *MattermostRenderer) Name() string {
+	return "mattermost"
+}
+
+func (mm *MattermostRenderer) attachments(a *Art, qs string, match MatchSummary, shuffle ShuffleState) []MattermostAttachment {
+	attachment := MattermostAttachment{
+		Text:   fmt.Sprintf("```\n%s\n```", strings.Trim(a.blob, "\n")),
+		Footer: match.String(),
+	}
+
+	postIt := MattermostAttachAction{Name: "Post it!"}
+	postIt.Integration.Context = map[string]string{"value": fmt.Sprintf("%d/%s", a.id, qs)}
+
+	shuffleAction := MattermostAttachAction{Name: "Shuffle!"}
+	shuffleAction.Integration.Context = map[string]string{"value": shuffle.Value(qs)}
+
+	attachment.Actions = []MattermostAttachAction{postIt, shuffleAction}
+	return []MattermostAttachment{attachment}
+}
+
+func (mm *MattermostRenderer) RenderArt(c *gin.Context, a *Art, qs string, match MatchSummary, shuffle ShuffleState) {
+	response := MattermostResponse{
+		ResponseType: "in_channel",
+		Attachments:  mm.attachments(a, qs, match, shuffle),
+	}
+	c.JSON(200, &response)
+}
+
+func (mm *MattermostRenderer) RenderNotFound(c *gin.Context, _qs string) {
+	response := MattermostResponse{
+		ResponseType: "ephemeral",
+		Text:         "couldnt find anything.... try something else or help me to add more ascii art",
+	}
+	c.JSON(200, &response)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PostMessage struct {
+	User    string   `json:"user"`
+	Channel string   `json:"channel"`
+	Blocks  []*Block `json:"blocks,omitempty"`
+}
+
+type SlackResponse struct {
+	ResponseType    string   `json:"response_type,omitempty"`
+	ReplaceOriginal bool     `json:"replace_original,omitempty"`
+	DeleteOriginal  bool     `json:"delete_original,omitempty"`
+	Blocks          []*Block `json:"blocks,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+type Block struct {
+	Type     string     `json:"type,omitempty"`
+	Text     *Text      `json:"text,omitempty"`
+	BlockID  string     `json:"block_id,omitempty"`
+	Elements []*Element `json:"elements,omitempty"`
+}
+
+type Element struct {
+	Type     string `json:"type,omitempty"`
+	Style    string `json:"style,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Value    string `json:"value,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Text     *Text  `json:"text,omitempty"`
+}
+
+// SlackRenderer renders search results as Slack Block Kit messages. This is
+// the original, and still default, chat adapter.
+type SlackRenderer struct{}
+
+func (s *SlackRenderer) Name() string {
+	return "slack"
+}
+
+func (s *SlackRenderer) blocks(a *Art, _qs string) []*Block {
+	return []*Block{
+		{
+			Type: "section",
+			Text: &Text{
+				Type: "mrkdwn", Text: fmt.Sprintf("```\n%s\n```", strings.Trim(a.blob, "\n")),
+			},
+		},
+	}
+}
+
+func (s *SlackRenderer) buttons(a *Art, qs string, shuffle ShuffleState) *Block {
+	return &Block{
+		BlockID: "action_123",
+		Type:    "actions",
+		Elements: []*Element{
+			{
+				Type: "button",
+				Text: &Text{
+					Type: "plain_text",
+					Text: "Post it!",
+				},
+				Style:    "primary",
+				Value:    fmt.Sprintf("%d/%s", a.id, qs),
+				ActionID: "post_it",
+			},
+			{
+				Type: "button",
+				Text: &Text{
+					Type: "plain_text",
+					Text: "Shuffle!",
+				},
+				ActionID: "shuffle",
+				Value:    shuffle.Value(qs),
+			},
+		},
+	}
+}
+
+func (s *SlackRenderer) context(match MatchSummary) *Block {
+	return &Block{
+		Type: "context",
+		Elements: []*Element{
+			{
+				Type: "mrkdwn",
+				Text: &Text{Type: "mrkdwn", Text: match.String()},
+			},
+		},
+	}
+}
+
+func (s *SlackRenderer) RenderArt(c *gin.Context, a *Art, qs string, match MatchSummary, shuffle ShuffleState) {
+	blocks := append(s.blocks(a, qs), s.buttons(a, qs, shuffle), s.context(match))
+	response := SlackResponse{
+		ResponseType: "in_channel",
+		Blocks:       blocks,
+	}
+	c.JSON(200, &response)
+}
+
+func (s *SlackRenderer) RenderNotFound(c *gin.Context, _qs string) {
+	response := SlackResponse{
+		Blocks: []*Block{
+			{
+				Type: "section",
+				Text: &Text{
+					Type: "mrkdwn", Text: fmt.Sprintf("```\n%s\n```", "couldnt find anything.... try something else or help me to add more ascii art"),
+				},
+			},
+		},
+	}
+	c.JSON(200, response)
+}
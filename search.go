@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	iq "github.com/rekki/go-query"
+	analyzer "github.com/rekki/go-query-analyze"
+	index "github.com/rekki/go-query-index"
+)
+
+// MatchLevel describes how much of a query actually hit a given result.
+type MatchLevel string
+
+const (
+	MatchNone    MatchLevel = "none"
+	MatchPartial MatchLevel = "partial"
+	MatchFull    MatchLevel = "full"
+)
+
+// MatchSummary explains why a piece of art matched a query: which of the
+// query's (stemmed, stopword-filtered) terms hit, and which tags/blob
+// shingles they hit in.
+type MatchSummary struct {
+	Level           MatchLevel `json:"match_level"`
+	MatchedWords    []string   `json:"matched_words"`
+	MatchedTags     []string   `json:"matched_tags,omitempty"`
+	MatchedShingles []string   `json:"matched_shingles,omitempty"`
+}
+
+// SearchHit is a single ranked result, annotated with why it matched, as
+// returned by the GET /search JSON endpoint.
+type SearchHit struct {
+	ID    int      `json:"id"`
+	Blob  string   `json:"blob"`
+	Tags  []string `json:"tags"`
+	Score float32  `json:"score"`
+	MatchSummary
+}
+
+// Searcher runs queries against a MemOnlyIndex, using the same perField
+// analyzers the index was built with so match explanations reflect exactly
+// what ended up in the postings.
+type Searcher struct {
+	index    *index.MemOnlyIndex
+	perField map[string]*analyzer.Analyzer
+}
+
+func NewSearcher(idx *index.MemOnlyIndex, perField map[string]*analyzer.Analyzer) *Searcher {
+	return &Searcher{index: idx, perField: perField}
+}
+
+func (s *Searcher) query(qs string) iq.Query {
+	perLang := make([]iq.Query, 0, len(Languages))
+	for _, lang := range Languages {
+		perLang = append(perLang, iq.DisMax(0.1,
+			iq.Or(s.index.Terms(FieldForLang("tags", lang), qs)...),
+			iq.Or(s.index.Terms(FieldForLang("blob", lang), qs)...),
+		))
+	}
+	return iq.Or(perLang...)
+}
+
+// RankedCandidates returns up to topK Arts for qs, ordered by real DisMax
+// relevance (best first). It backs both Best (always the top hit) and the
+// Shuffle button (a deterministic walk over this same ranked list).
+func (s *Searcher) RankedCandidates(qs string, topK int) []*Art {
+	result := s.index.TopN(topK, s.query(qs), nil)
+
+	candidates := make([]*Art, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		candidates = append(candidates, h.Document.(*Art))
+	}
+	return candidates
+}
+
+// Best returns the single most relevant match for qs among the topK ranked
+// candidates, or nil if nothing matched.
+func (s *Searcher) Best(qs string, topK int) *Art {
+	candidates := s.RankedCandidates(qs, topK)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// TopN returns up to limit hits for qs, ranked by DisMax score and each
+// annotated with a MatchSummary.
+func (s *Searcher) TopN(qs string, limit int) []SearchHit {
+	result := s.index.TopN(limit, s.query(qs), nil)
+
+	hits := make([]SearchHit, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		art := h.Document.(*Art)
+		hits = append(hits, SearchHit{
+			ID:           art.id,
+			Blob:         art.blob,
+			Tags:         art.tags,
+			Score:        h.Score,
+			MatchSummary: s.Match(art, qs),
+		})
+	}
+	return hits
+}
+
+// Match explains why art matched qs: it re-runs the same analyzer chain
+// used at index time over qs, art's tags and art's blob, so "matched" means
+// exactly what made it into the postings (stemmed, stopword-filtered terms),
+// not a raw substring check.
+func (s *Searcher) Match(art *Art, qs string) MatchSummary {
+	ana, ok := s.perField[FieldForLang("blob", art.lang)]
+	if !ok {
+		return MatchSummary{Level: MatchNone}
+	}
+
+	queryTerms := ana.AnalyzeSearch(qs)
+	if len(queryTerms) == 0 {
+		return MatchSummary{Level: MatchNone}
+	}
+
+	hit := map[string]bool{}
+
+	var matchedTags []string
+	for _, tag := range art.tags {
+		tagTerms := toSet(ana.AnalyzeIndex(tag))
+		matchedThisTag := false
+		for _, qt := range queryTerms {
+			if tagTerms[qt] {
+				hit[qt] = true
+				matchedThisTag = true
+			}
+		}
+		if matchedThisTag {
+			matchedTags = append(matchedTags, tag)
+		}
+	}
+
+	blobTerms := toSet(ana.AnalyzeIndex(art.blob))
+	var matchedShingles []string
+	for _, qt := range queryTerms {
+		if blobTerms[qt] && !hit[qt] {
+			matchedShingles = append(matchedShingles, qt)
+		}
+		if blobTerms[qt] {
+			hit[qt] = true
+		}
+	}
+
+	matchedWords := make([]string, 0, len(hit))
+	for _, qt := range queryTerms {
+		if hit[qt] {
+			matchedWords = append(matchedWords, qt)
+		}
+	}
+
+	level := MatchNone
+	switch {
+	case len(matchedWords) == 0:
+		level = MatchNone
+	case len(matchedWords) == len(queryTerms):
+		level = MatchFull
+	default:
+		level = MatchPartial
+	}
+
+	return MatchSummary{
+		Level:           level,
+		MatchedWords:    matchedWords,
+		MatchedTags:     matchedTags,
+		MatchedShingles: matchedShingles,
+	}
+}
+
+// String renders a MatchSummary as a short one-line explanation suitable
+// for a chat context/footer line, e.g. "full match on: cat, fluffy".
+func (m MatchSummary) String() string {
+	if len(m.MatchedWords) == 0 {
+		return "no term matched"
+	}
+	return fmt.Sprintf("%s match on: %s", m.Level, strings.Join(m.MatchedWords, ", "))
+}
+
+func toSet(terms []string) map[string]bool {
+	out := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		out[t] = true
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultServerTimeout bounds how long reading request headers or writing a
+// response may take, guarding against slow-client resource exhaustion.
+const defaultServerTimeout = 15 * time.Second
+
+// shutdownGrace is how long in-flight requests get to finish once a
+// shutdown signal arrives before the server gives up on them.
+const shutdownGrace = 10 * time.Second
+
+// RunGracefully serves handler on addr with sane header/write timeouts, and
+// blocks until SIGINT or SIGTERM, at which point it drains in-flight
+// requests and returns once shutdown completes (or the grace period
+// expires).
+func RunGracefully(handler http.Handler, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: defaultServerTimeout,
+		WriteTimeout:      defaultServerTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case sig := <-stop:
+		log.Printf("received %s, shutting down", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
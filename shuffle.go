@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShuffleState is the seed/counter pair embedded in a "Shuffle!" button's
+// Value so that repeated clicks step deterministically through the ranked
+// candidate list instead of re-rolling a fresh random pick each time.
+type ShuffleState struct {
+	Seed    int64
+	Counter int
+}
+
+// NewShuffleState starts a fresh shuffle sequence for a query, seeded off
+// the current time so different searches don't all shuffle identically.
+func NewShuffleState() ShuffleState {
+	return ShuffleState{Seed: time.Now().UnixNano(), Counter: 1}
+}
+
+// Value encodes state and the original query into a button Value.
+func (s ShuffleState) Value(qs string) string {
+	return strconv.FormatInt(s.Seed, 10) + "/" + strconv.Itoa(s.Counter) + "/" + qs
+}
+
+// Next is the state to embed in the button that's shown alongside the
+// result Shuffle just produced, so the click after that advances further.
+func (s ShuffleState) Next() ShuffleState {
+	return ShuffleState{Seed: s.Seed, Counter: s.Counter + 1}
+}
+
+// ParseShuffleValue decodes a "Shuffle!" button's Value back into its
+// ShuffleState and original query string.
+func ParseShuffleValue(value string) (ShuffleState, string, bool) {
+	parts := strings.SplitN(value, "/", 3)
+	if len(parts) != 3 {
+		return ShuffleState{}, "", false
+	}
+
+	seed, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ShuffleState{}, "", false
+	}
+	counter, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ShuffleState{}, "", false
+	}
+
+	return ShuffleState{Seed: seed, Counter: counter}, parts[2], true
+}
+
+// PickShuffled returns the candidate at position `counter` of a
+// deterministic permutation of candidates, seeded by seed, that excludes
+// candidates[0] — the rank-0 result Best() already returned and the caller
+// already displayed before any Shuffle click happened. counter is 1 for the
+// first click (see NewShuffleState), so it indexes directly into the
+// permutation of the remaining candidates. The same (seed, len(candidates))
+// always yields the same permutation, so advancing counter across clicks
+// walks every alternate exactly once before repeating, and the first click
+// is guaranteed to never just re-show what's already on screen.
+func PickShuffled(candidates []*Art, seed int64, counter int) *Art {
+	if len(candidates) == 0 {
+		return nil
+	}
+	rest := candidates[1:]
+	if len(rest) == 0 {
+		return candidates[0]
+	}
+
+	order := rand.New(rand.NewSource(seed)).Perm(len(rest))
+	idx := counter - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return rest[order[idx%len(order)]]
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher keeps a Reindexer in sync with live changes to the art corpus,
+// so new/edited/removed .txt files show up in search without a restart.
+type Watcher struct {
+	fs   *fsnotify.Watcher
+	rx   *Reindexer
+	done chan struct{}
+}
+
+// NewWatcher starts watching root (and every subdirectory under it) for
+// file changes that should be reflected in rx.
+func NewWatcher(root string, rx *Reindexer) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fs.Add(p)
+		}
+		return nil
+	})
+	if err != nil {
+		fs.Close()
+		return nil, err
+	}
+
+	return &Watcher{fs: fs, rx: rx, done: make(chan struct{})}, nil
+}
+
+// Run processes filesystem events until Close is called. Call it in its own
+// goroutine.
+func (w *Watcher) Run() {
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("watcher error: %v", err)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) handle(event fsnotify.Event) {
+	switch {
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		w.rx.RemovePath(event.Name)
+	case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+		if err := w.rx.IndexPath(event.Name); err != nil {
+			log.Printf("failed to index %v: %v", event.Name, err)
+		}
+	}
+}
+
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fs.Close()
+}